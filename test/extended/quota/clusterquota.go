@@ -12,6 +12,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	utilwait "k8s.io/apimachinery/pkg/util/wait"
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/kubernetes/test/e2e/framework"
@@ -144,7 +145,10 @@ var _ = g.Describe("[sig-api-machinery][Feature:ClusterResourceQuota]", func() {
 			}); err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
-			if _, err := clusterAdminKubeClient.CoreV1().ConfigMaps(secondProjectName).Create(context.Background(), configmap, metav1.CreateOptions{}); !apierrors.IsForbidden(err) {
+			if err := waitForForbidden(func() error {
+				_, err := clusterAdminKubeClient.CoreV1().ConfigMaps(secondProjectName).Create(context.Background(), configmap, metav1.CreateOptions{})
+				return err
+			}); err != nil {
 				list, err := clusterAdminQuotaClient.QuotaV1().AppliedClusterResourceQuotas(secondProjectName).List(context.Background(), metav1.ListOptions{})
 				if err == nil {
 					t.Errorf("quota is %#v", list)
@@ -176,7 +180,10 @@ var _ = g.Describe("[sig-api-machinery][Feature:ClusterResourceQuota]", func() {
 				t.Fatalf("unexpected error: %v", err)
 			}
 
-			if _, err := clusterAdminImageClient.ImageV1().ImageStreams(secondProjectName).Create(context.Background(), imagestream, metav1.CreateOptions{}); !apierrors.IsForbidden(err) {
+			if err := waitForForbidden(func() error {
+				_, err := clusterAdminImageClient.ImageV1().ImageStreams(secondProjectName).Create(context.Background(), imagestream, metav1.CreateOptions{})
+				return err
+			}); err != nil {
 				list, err := clusterAdminQuotaClient.QuotaV1().AppliedClusterResourceQuotas(secondProjectName).List(context.Background(), metav1.ListOptions{})
 				if err == nil {
 					t.Errorf("quota is %#v", list)
@@ -206,6 +213,25 @@ func waitForQuotaLabeling(clusterAdminClient quotaclient.Interface, namespaceNam
 	})
 }
 
+// mandatoryConfigMapNames are provisioned into every namespace and must be accounted for by any
+// ConfigMap hard limit asserted against a namespace's exact usage count.
+var mandatoryConfigMapNames = []string{"kube-root-ca.crt", "openshift-service-ca.crt"}
+
+// waitForMandatoryConfigMaps waits for the mandatory per-namespace ConfigMaps to appear in
+// namespaceName, so that a subsequent quota usage assertion isn't racing their creation.
+func waitForMandatoryConfigMaps(clusterAdminCoreClient corev1client.CoreV1Interface, namespaceName string) error {
+	for _, cm := range mandatoryConfigMapNames {
+		_, err := exutil.WaitForCMState(context.Background(), clusterAdminCoreClient, namespaceName, cm, func(cm *corev1.ConfigMap) (bool, error) {
+			framework.Logf("configmap %q is present in namespace %q", cm.Name, namespaceName)
+			return true, nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func labelNamespace(clusterAdminKubeClient corev1client.NamespacesGetter, labelKey, namespaceName string) error {
 	ns1, err := clusterAdminKubeClient.Namespaces().Get(context.Background(), namespaceName, metav1.GetOptions{})
 	if err != nil {
@@ -221,28 +247,69 @@ func labelNamespace(clusterAdminKubeClient corev1client.NamespacesGetter, labelK
 	return nil
 }
 
+// waitForForbidden retries createFn until it observes a Forbidden admission response. The
+// ClusterResourceQuota controller's published status and the quota admission plugin's usage
+// cache are reconciled independently, so observing the expected status via waitForQuotaStatus
+// does not guarantee admission has already caught up; callers expecting enforcement of a quota
+// that was just satisfied should poll here instead of asserting Forbidden on the first attempt.
+func waitForForbidden(createFn func() error) error {
+	var lastErr error
+	err := utilwait.PollImmediate(100*time.Millisecond, 15*time.Second, func() (done bool, err error) {
+		lastErr = createFn()
+		return apierrors.IsForbidden(lastErr), nil
+	})
+	if err != nil {
+		return fmt.Errorf("expected a Forbidden response, got: %v", lastErr)
+	}
+	return nil
+}
+
+// waitForQuotaStatus watches the named ClusterResourceQuota and returns as soon as a status
+// update satisfies conditionFn, driven by server-pushed watch events rather than a fixed sleep
+// after a successful poll. ClusterResourceQuotaStatus carries no generation-style fencing field,
+// so this cannot prove the status came from any particular reconcile cycle; callers that go on to
+// assert admission enforcement immediately afterward should retry that assertion too (see
+// waitForForbidden), since the admission plugin's usage cache catches up independently of when
+// the controller publishes status.
 func waitForQuotaStatus(clusterAdminClient quotaclient.Interface, name string, conditionFn func(*quotav1.ClusterResourceQuota) error) error {
+	fieldSelector := fields.OneTermEqualSelector("metadata.name", name).String()
+
+	quota, err := clusterAdminClient.QuotaV1().ClusterResourceQuotas().Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
 	var pollErr error
-	err := utilwait.PollImmediate(100*time.Millisecond, 30*time.Second, func() (done bool, err error) {
-		quota, err := clusterAdminClient.QuotaV1().ClusterResourceQuotas().Get(context.Background(), name, metav1.GetOptions{})
-		if err != nil {
-			pollErr = err
-			return false, nil
-		}
-		err = conditionFn(quota)
-		if err == nil {
-			return true, nil
-		}
+	if err := conditionFn(quota); err == nil {
+		return nil
+	} else {
 		pollErr = err
-		return false, nil
-	})
-	if err == nil {
-		// since now we run each process separately we need to wait for the informers
-		// to catch up on the update and only then continue
-		time.Sleep(3 * time.Second)
 	}
+
+	w, err := clusterAdminClient.QuotaV1().ClusterResourceQuotas().Watch(context.Background(), metav1.ListOptions{FieldSelector: fieldSelector, ResourceVersion: quota.ResourceVersion})
 	if err != nil {
-		err = fmt.Errorf("%s: %s", err, pollErr)
+		return err
+	}
+	defer w.Stop()
+
+	timeout := time.After(30 * time.Second)
+	for {
+		select {
+		case event, ok := <-w.ResultChan():
+			if !ok {
+				return fmt.Errorf("watch closed before quota status satisfied condition: %s", pollErr)
+			}
+			quota, ok := event.Object.(*quotav1.ClusterResourceQuota)
+			if !ok {
+				continue
+			}
+			if err := conditionFn(quota); err == nil {
+				return nil
+			} else {
+				pollErr = err
+			}
+		case <-timeout:
+			return fmt.Errorf("timed out waiting for quota status to satisfy condition: %s", pollErr)
+		}
 	}
-	return err
 }
@@ -0,0 +1,124 @@
+package quota
+
+import (
+	"context"
+	"fmt"
+
+	g "github.com/onsi/ginkgo/v2"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	quotav1 "github.com/openshift/api/quota/v1"
+	routev1 "github.com/openshift/api/route/v1"
+	exutil "github.com/openshift/origin/test/extended/util"
+)
+
+var _ = g.Describe("[sig-api-machinery][Feature:ClusterResourceQuota]", func() {
+	defer g.GinkgoRecover()
+	oc := exutil.NewCLI("crq-count")
+
+	g.Describe("Cluster resource quota generic object counts", func() {
+		g.It("should control count/<resource>.<group> quotas across namespaces [apigroup:quota.openshift.io][apigroup:route.openshift.io]", func() {
+			t := g.GinkgoT(1)
+
+			clusterAdminKubeClient := oc.AdminKubeClient()
+			clusterAdminQuotaClient := oc.AdminQuotaClient()
+			clusterAdminRouteClient := oc.AdminRouteClient()
+
+			const deploymentCountResource = corev1.ResourceName("count/deployments.apps")
+			const routeCountResource = corev1.ResourceName("count/routes.route.openshift.io")
+
+			labelSelectorKey := "count-" + oc.Namespace()
+			cq := &quotav1.ClusterResourceQuota{
+				ObjectMeta: metav1.ObjectMeta{Name: "count-" + oc.Namespace()},
+				Spec: quotav1.ClusterResourceQuotaSpec{
+					Selector: quotav1.ClusterResourceQuotaSelector{
+						LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{labelSelectorKey: "bar"}},
+					},
+					Quota: corev1.ResourceQuotaSpec{
+						Hard: corev1.ResourceList{
+							deploymentCountResource: resource.MustParse("1"),
+							routeCountResource:      resource.MustParse("1"),
+						},
+					},
+				},
+			}
+			if _, err := clusterAdminQuotaClient.QuotaV1().ClusterResourceQuotas().Create(context.Background(), cq, metav1.CreateOptions{}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			oc.AddResourceToDelete(quotav1.GroupVersion.WithResource("clusterresourcequotas"), cq)
+
+			projectName := oc.SetupProject()
+			if err := labelNamespace(clusterAdminKubeClient.CoreV1(), labelSelectorKey, projectName); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := waitForQuotaLabeling(clusterAdminQuotaClient, projectName); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			replicas := int32(1)
+			deployment := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{GenerateName: "test"},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: &replicas,
+					Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}},
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "test"}},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{{Name: "c", Image: "image"}},
+						},
+					},
+				},
+			}
+			if _, err := clusterAdminKubeClient.AppsV1().Deployments(projectName).Create(context.Background(), deployment, metav1.CreateOptions{}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := waitForQuotaStatus(clusterAdminQuotaClient, cq.Name, func(quota *quotav1.ClusterResourceQuota) error {
+				q := quota.Status.Total.Used[deploymentCountResource]
+				if i, ok := q.AsInt64(); ok && i == 1 {
+					return nil
+				}
+				return fmt.Errorf("quota=%+v, expected 1", q)
+			}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			deployment.GenerateName = "test"
+			deployment.ResourceVersion = ""
+			if err := waitForForbidden(func() error {
+				_, err := clusterAdminKubeClient.AppsV1().Deployments(projectName).Create(context.Background(), deployment, metav1.CreateOptions{})
+				return err
+			}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			route := &routev1.Route{
+				ObjectMeta: metav1.ObjectMeta{GenerateName: "test"},
+				Spec: routev1.RouteSpec{
+					To: routev1.RouteTargetReference{Kind: "Service", Name: "test"},
+				},
+			}
+			if _, err := clusterAdminRouteClient.RouteV1().Routes(projectName).Create(context.Background(), route, metav1.CreateOptions{}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := waitForQuotaStatus(clusterAdminQuotaClient, cq.Name, func(quota *quotav1.ClusterResourceQuota) error {
+				q := quota.Status.Total.Used[routeCountResource]
+				if i, ok := q.AsInt64(); ok && i == 1 {
+					return nil
+				}
+				return fmt.Errorf("quota=%+v, expected 1", q)
+			}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			route.ResourceVersion = ""
+			if err := waitForForbidden(func() error {
+				_, err := clusterAdminRouteClient.RouteV1().Routes(projectName).Create(context.Background(), route, metav1.CreateOptions{})
+				return err
+			}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	})
+})
@@ -0,0 +1,30 @@
+package quota
+
+import (
+	g "github.com/onsi/ginkgo/v2"
+)
+
+// Multi-tier ClusterResourceQuota hierarchies (a child CRQ nominating a parent CRQ whose hard
+// limits bound the sum of its children's usage) require a Parent field on
+// quotav1.ClusterResourceQuotaSpec plus matching reconciliation and admission-plugin support. None
+// of that exists yet: the vendored quotav1.ClusterResourceQuotaSpec has no Parent field, and this
+// origin-only change can't add one since the type is defined in github.com/openshift/api. The
+// tests below are left as documented skips rather than silently dropped, and should be filled in
+// once the API field and its controller/admission support land.
+var _ = g.Describe("[sig-api-machinery][Feature:ClusterResourceQuota]", func() {
+	defer g.GinkgoRecover()
+
+	g.Describe("Cluster resource quota hierarchy", func() {
+		g.It("should aggregate a child ClusterResourceQuota's usage into its parent [apigroup:quota.openshift.io]", func() {
+			g.Skip("ClusterResourceQuotaSpec has no Parent field upstream; hierarchy aggregation cannot be exercised until the API and its controller support land")
+		})
+
+		g.It("should reject admission of a child ClusterResourceQuota whose hard exceeds the parent's remaining hard [apigroup:quota.openshift.io]", func() {
+			g.Skip("ClusterResourceQuotaSpec has no Parent field upstream; there is no admission plugin enforcing a child's hard against its parent's remaining hard to exercise")
+		})
+
+		g.It("should reject a child ClusterResourceQuota that would create a parent cycle [apigroup:quota.openshift.io]", func() {
+			g.Skip("ClusterResourceQuotaSpec has no Parent field upstream; there is no validation rejecting parent cycles to exercise")
+		})
+	})
+})
@@ -0,0 +1,388 @@
+package quota
+
+import (
+	"context"
+	"fmt"
+
+	g "github.com/onsi/ginkgo/v2"
+
+	corev1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	quotav1 "github.com/openshift/api/quota/v1"
+	exutil "github.com/openshift/origin/test/extended/util"
+)
+
+var _ = g.Describe("[sig-api-machinery][Feature:ClusterResourceQuota]", func() {
+	defer g.GinkgoRecover()
+	oc := exutil.NewCLI("crq-scopes")
+
+	g.Describe("Cluster resource quota scopes", func() {
+		g.It("should control resource limits across namespaces by scope [apigroup:quota.openshift.io]", func() {
+			t := g.GinkgoT(1)
+
+			clusterAdminKubeClient := oc.AdminKubeClient()
+			clusterAdminQuotaClient := oc.AdminQuotaClient()
+
+			priorityClassName := "crq-scopes-" + oc.Namespace()
+			priorityClass := &schedulingv1.PriorityClass{
+				ObjectMeta: metav1.ObjectMeta{Name: priorityClassName},
+				Value:      1000,
+			}
+			if _, err := clusterAdminKubeClient.SchedulingV1().PriorityClasses().Create(context.Background(), priorityClass, metav1.CreateOptions{}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			oc.AddResourceToDelete(schedulingv1.SchemeGroupVersion.WithResource("priorityclasses"), priorityClass)
+
+			labelSelectorKey := "scopes-" + oc.Namespace()
+			cq := &quotav1.ClusterResourceQuota{
+				ObjectMeta: metav1.ObjectMeta{Name: "scopes-" + oc.Namespace()},
+				Spec: quotav1.ClusterResourceQuotaSpec{
+					Selector: quotav1.ClusterResourceQuotaSelector{
+						LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{labelSelectorKey: "bar"}},
+					},
+					Quota: corev1.ResourceQuotaSpec{
+						ScopeSelector: &corev1.ScopeSelector{
+							MatchExpressions: []corev1.ScopedResourceSelectorRequirement{
+								{ScopeName: corev1.ResourceQuotaScopePriorityClass, Operator: corev1.ScopeSelectorOpIn, Values: []string{priorityClassName}},
+							},
+						},
+						Hard: corev1.ResourceList{
+							corev1.ResourcePods: resource.MustParse("1"),
+						},
+					},
+				},
+			}
+			if _, err := clusterAdminQuotaClient.QuotaV1().ClusterResourceQuotas().Create(context.Background(), cq, metav1.CreateOptions{}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			oc.AddResourceToDelete(quotav1.GroupVersion.WithResource("clusterresourcequotas"), cq)
+
+			firstProjectName := oc.SetupProject()
+			secondProjectName := oc.SetupProject()
+
+			for _, ns := range []string{firstProjectName, secondProjectName} {
+				if err := labelNamespace(clusterAdminKubeClient.CoreV1(), labelSelectorKey, ns); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if err := waitForQuotaLabeling(clusterAdminQuotaClient, ns); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			if err := waitForQuotaStatus(clusterAdminQuotaClient, cq.Name, func(quota *quotav1.ClusterResourceQuota) error {
+				if !quota.Status.Total.Hard[corev1.ResourcePods].Equal(resource.MustParse("1")) {
+					return fmt.Errorf("hard pod count not yet reflected: %#v", quota.Status.Total.Hard)
+				}
+				return nil
+			}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if _, err := clusterAdminKubeClient.CoreV1().Pods(firstProjectName).Create(context.Background(), newScopedPod("scoped", priorityClassName), metav1.CreateOptions{}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := waitForQuotaStatus(clusterAdminQuotaClient, cq.Name, func(quota *quotav1.ClusterResourceQuota) error {
+				q := quota.Status.Total.Used[corev1.ResourcePods]
+				if i, ok := q.AsInt64(); ok && i == 1 {
+					return nil
+				}
+				return fmt.Errorf("quota=%+v, expected 1", q)
+			}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if err := waitForForbidden(func() error {
+				_, err := clusterAdminKubeClient.CoreV1().Pods(secondProjectName).Create(context.Background(), newScopedPod("scoped", priorityClassName), metav1.CreateOptions{})
+				return err
+			}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// A pod outside the selected priority class does not count against the scoped quota.
+			if _, err := clusterAdminKubeClient.CoreV1().Pods(secondProjectName).Create(context.Background(), newScopedPod("unscoped", ""), metav1.CreateOptions{}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+
+		g.It("should control resource limits across namespaces by BestEffort/NotBestEffort scope [apigroup:quota.openshift.io]", func() {
+			t := g.GinkgoT(1)
+
+			clusterAdminKubeClient := oc.AdminKubeClient()
+			clusterAdminQuotaClient := oc.AdminQuotaClient()
+
+			labelSelectorKey := "besteffort-" + oc.Namespace()
+			cq := &quotav1.ClusterResourceQuota{
+				ObjectMeta: metav1.ObjectMeta{Name: "besteffort-" + oc.Namespace()},
+				Spec: quotav1.ClusterResourceQuotaSpec{
+					Selector: quotav1.ClusterResourceQuotaSelector{
+						LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{labelSelectorKey: "bar"}},
+					},
+					Quota: corev1.ResourceQuotaSpec{
+						Scopes: []corev1.ResourceQuotaScope{corev1.ResourceQuotaScopeBestEffort},
+						Hard: corev1.ResourceList{
+							corev1.ResourcePods: resource.MustParse("1"),
+						},
+					},
+				},
+			}
+			if _, err := clusterAdminQuotaClient.QuotaV1().ClusterResourceQuotas().Create(context.Background(), cq, metav1.CreateOptions{}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			oc.AddResourceToDelete(quotav1.GroupVersion.WithResource("clusterresourcequotas"), cq)
+
+			projectName := oc.SetupProject()
+			if err := labelNamespace(clusterAdminKubeClient.CoreV1(), labelSelectorKey, projectName); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := waitForQuotaLabeling(clusterAdminQuotaClient, projectName); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// A NotBestEffort pod (it declares requests/limits) never counts against a BestEffort-scoped quota.
+			if _, err := clusterAdminKubeClient.CoreV1().Pods(projectName).Create(context.Background(), newNonBestEffortPod("guaranteed"), metav1.CreateOptions{}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := waitForQuotaStatus(clusterAdminQuotaClient, cq.Name, func(quota *quotav1.ClusterResourceQuota) error {
+				q := quota.Status.Total.Used[corev1.ResourcePods]
+				if i, ok := q.AsInt64(); ok && i == 0 {
+					return nil
+				}
+				return fmt.Errorf("quota=%+v, expected 0", q)
+			}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if _, err := clusterAdminKubeClient.CoreV1().Pods(projectName).Create(context.Background(), newScopedPod("besteffort", ""), metav1.CreateOptions{}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := waitForQuotaStatus(clusterAdminQuotaClient, cq.Name, func(quota *quotav1.ClusterResourceQuota) error {
+				q := quota.Status.Total.Used[corev1.ResourcePods]
+				if i, ok := q.AsInt64(); ok && i == 1 {
+					return nil
+				}
+				return fmt.Errorf("quota=%+v, expected 1", q)
+			}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if err := waitForForbidden(func() error {
+				_, err := clusterAdminKubeClient.CoreV1().Pods(projectName).Create(context.Background(), newScopedPod("besteffort", ""), metav1.CreateOptions{})
+				return err
+			}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+
+		g.It("should control resource limits across namespaces by Terminating/NotTerminating scope [apigroup:quota.openshift.io]", func() {
+			t := g.GinkgoT(1)
+
+			clusterAdminKubeClient := oc.AdminKubeClient()
+			clusterAdminQuotaClient := oc.AdminQuotaClient()
+
+			labelSelectorKey := "terminating-" + oc.Namespace()
+			cq := &quotav1.ClusterResourceQuota{
+				ObjectMeta: metav1.ObjectMeta{Name: "terminating-" + oc.Namespace()},
+				Spec: quotav1.ClusterResourceQuotaSpec{
+					Selector: quotav1.ClusterResourceQuotaSelector{
+						LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{labelSelectorKey: "bar"}},
+					},
+					Quota: corev1.ResourceQuotaSpec{
+						Scopes: []corev1.ResourceQuotaScope{corev1.ResourceQuotaScopeTerminating},
+						Hard: corev1.ResourceList{
+							corev1.ResourcePods: resource.MustParse("1"),
+						},
+					},
+				},
+			}
+			if _, err := clusterAdminQuotaClient.QuotaV1().ClusterResourceQuotas().Create(context.Background(), cq, metav1.CreateOptions{}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			oc.AddResourceToDelete(quotav1.GroupVersion.WithResource("clusterresourcequotas"), cq)
+
+			projectName := oc.SetupProject()
+			if err := labelNamespace(clusterAdminKubeClient.CoreV1(), labelSelectorKey, projectName); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := waitForQuotaLabeling(clusterAdminQuotaClient, projectName); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// A NotTerminating pod (no activeDeadlineSeconds) never counts against a
+			// Terminating-scoped quota.
+			if _, err := clusterAdminKubeClient.CoreV1().Pods(projectName).Create(context.Background(), newScopedPod("not-terminating", ""), metav1.CreateOptions{}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := waitForQuotaStatus(clusterAdminQuotaClient, cq.Name, func(quota *quotav1.ClusterResourceQuota) error {
+				q := quota.Status.Total.Used[corev1.ResourcePods]
+				if i, ok := q.AsInt64(); ok && i == 0 {
+					return nil
+				}
+				return fmt.Errorf("quota=%+v, expected 0", q)
+			}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if _, err := clusterAdminKubeClient.CoreV1().Pods(projectName).Create(context.Background(), newTerminatingPod("terminating"), metav1.CreateOptions{}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := waitForQuotaStatus(clusterAdminQuotaClient, cq.Name, func(quota *quotav1.ClusterResourceQuota) error {
+				q := quota.Status.Total.Used[corev1.ResourcePods]
+				if i, ok := q.AsInt64(); ok && i == 1 {
+					return nil
+				}
+				return fmt.Errorf("quota=%+v, expected 1", q)
+			}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if err := waitForForbidden(func() error {
+				_, err := clusterAdminKubeClient.CoreV1().Pods(projectName).Create(context.Background(), newTerminatingPod("terminating"), metav1.CreateOptions{})
+				return err
+			}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+
+		g.It("should control resource limits across namespaces by CrossNamespacePodAffinity scope [apigroup:quota.openshift.io]", func() {
+			t := g.GinkgoT(1)
+
+			clusterAdminKubeClient := oc.AdminKubeClient()
+			clusterAdminQuotaClient := oc.AdminQuotaClient()
+
+			labelSelectorKey := "crossns-" + oc.Namespace()
+			cq := &quotav1.ClusterResourceQuota{
+				ObjectMeta: metav1.ObjectMeta{Name: "crossns-" + oc.Namespace()},
+				Spec: quotav1.ClusterResourceQuotaSpec{
+					Selector: quotav1.ClusterResourceQuotaSelector{
+						LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{labelSelectorKey: "bar"}},
+					},
+					Quota: corev1.ResourceQuotaSpec{
+						Scopes: []corev1.ResourceQuotaScope{corev1.ResourceQuotaScopeCrossNamespacePodAffinity},
+						Hard: corev1.ResourceList{
+							corev1.ResourcePods: resource.MustParse("1"),
+						},
+					},
+				},
+			}
+			if _, err := clusterAdminQuotaClient.QuotaV1().ClusterResourceQuotas().Create(context.Background(), cq, metav1.CreateOptions{}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			oc.AddResourceToDelete(quotav1.GroupVersion.WithResource("clusterresourcequotas"), cq)
+
+			projectName := oc.SetupProject()
+			if err := labelNamespace(clusterAdminKubeClient.CoreV1(), labelSelectorKey, projectName); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := waitForQuotaLabeling(clusterAdminQuotaClient, projectName); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// A pod with no cross-namespace affinity terms never counts against this scope.
+			if _, err := clusterAdminKubeClient.CoreV1().Pods(projectName).Create(context.Background(), newScopedPod("no-affinity", ""), metav1.CreateOptions{}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := waitForQuotaStatus(clusterAdminQuotaClient, cq.Name, func(quota *quotav1.ClusterResourceQuota) error {
+				q := quota.Status.Total.Used[corev1.ResourcePods]
+				if i, ok := q.AsInt64(); ok && i == 0 {
+					return nil
+				}
+				return fmt.Errorf("quota=%+v, expected 0", q)
+			}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if _, err := clusterAdminKubeClient.CoreV1().Pods(projectName).Create(context.Background(), newCrossNamespaceAffinityPod("cross-ns"), metav1.CreateOptions{}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := waitForQuotaStatus(clusterAdminQuotaClient, cq.Name, func(quota *quotav1.ClusterResourceQuota) error {
+				q := quota.Status.Total.Used[corev1.ResourcePods]
+				if i, ok := q.AsInt64(); ok && i == 1 {
+					return nil
+				}
+				return fmt.Errorf("quota=%+v, expected 1", q)
+			}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if err := waitForForbidden(func() error {
+				_, err := clusterAdminKubeClient.CoreV1().Pods(projectName).Create(context.Background(), newCrossNamespaceAffinityPod("cross-ns"), metav1.CreateOptions{})
+				return err
+			}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	})
+})
+
+// newScopedPod builds a minimal best-effort pod, optionally assigned to priorityClassName, suitable
+// for exercising BestEffort and PriorityClass quota scopes.
+func newScopedPod(namePrefix, priorityClassName string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{GenerateName: namePrefix},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers: []corev1.Container{
+				{
+					Name:  "c",
+					Image: "image",
+				},
+			},
+		},
+	}
+	if priorityClassName != "" {
+		pod.Spec.PriorityClassName = priorityClassName
+	}
+	return pod
+}
+
+// newNonBestEffortPod builds a pod with requests and limits set on every container, placing it
+// in the NotBestEffort QoS class so it is excluded from a BestEffort-scoped quota.
+func newNonBestEffortPod(namePrefix string) *corev1.Pod {
+	quantity := resource.MustParse("100m")
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{GenerateName: namePrefix},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers: []corev1.Container{
+				{
+					Name:  "c",
+					Image: "image",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceCPU: quantity},
+						Limits:   corev1.ResourceList{corev1.ResourceCPU: quantity},
+					},
+				},
+			},
+		},
+	}
+}
+
+// newTerminatingPod builds a pod with an activeDeadlineSeconds set, placing it in the
+// Terminating scope so it counts against a Terminating-scoped quota.
+func newTerminatingPod(namePrefix string) *corev1.Pod {
+	pod := newScopedPod(namePrefix, "")
+	deadline := int64(3600)
+	pod.Spec.ActiveDeadlineSeconds = &deadline
+	return pod
+}
+
+// newCrossNamespaceAffinityPod builds a pod with a pod affinity term that selects namespaces by a
+// NamespaceSelector, placing it in the CrossNamespacePodAffinity scope.
+func newCrossNamespaceAffinityPod(namePrefix string) *corev1.Pod {
+	pod := newScopedPod(namePrefix, "")
+	pod.Spec.Affinity = &corev1.Affinity{
+		PodAffinity: &corev1.PodAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{
+				{
+					LabelSelector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}},
+					TopologyKey:       "kubernetes.io/hostname",
+					NamespaceSelector: &metav1.LabelSelector{},
+				},
+			},
+		},
+	}
+	return pod
+}
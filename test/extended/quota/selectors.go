@@ -0,0 +1,188 @@
+package quota
+
+import (
+	"context"
+	"fmt"
+
+	g "github.com/onsi/ginkgo/v2"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	quotav1 "github.com/openshift/api/quota/v1"
+	exutil "github.com/openshift/origin/test/extended/util"
+)
+
+var _ = g.Describe("[sig-api-machinery][Feature:ClusterResourceQuota]", func() {
+	defer g.GinkgoRecover()
+	oc := exutil.NewCLI("crq-selectors")
+
+	g.Describe("Cluster resource quota annotation selectors", func() {
+		g.It("should select namespaces by annotation and dynamically re-evaluate on annotation change [apigroup:quota.openshift.io]", func() {
+			t := g.GinkgoT(1)
+
+			clusterAdminKubeClient := oc.AdminKubeClient()
+			clusterAdminQuotaClient := oc.AdminQuotaClient()
+
+			requesterKey := "openshift.io/requester-" + oc.Namespace()
+
+			cq := &quotav1.ClusterResourceQuota{
+				ObjectMeta: metav1.ObjectMeta{Name: "annotated-" + oc.Namespace()},
+				Spec: quotav1.ClusterResourceQuotaSpec{
+					Selector: quotav1.ClusterResourceQuotaSelector{
+						AnnotationSelector: map[string]string{requesterKey: "alice"},
+					},
+					Quota: corev1.ResourceQuotaSpec{
+						Hard: corev1.ResourceList{
+							corev1.ResourceConfigMaps: resource.MustParse(fmt.Sprintf("%d", 1+len(mandatoryConfigMapNames))),
+						},
+					},
+				},
+			}
+			if _, err := clusterAdminQuotaClient.QuotaV1().ClusterResourceQuotas().Create(context.Background(), cq, metav1.CreateOptions{}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			oc.AddResourceToDelete(quotav1.GroupVersion.WithResource("clusterresourcequotas"), cq)
+
+			aliceProjectName := oc.SetupProject()
+			bobProjectName := oc.SetupProject()
+
+			if err := waitForMandatoryConfigMaps(clusterAdminKubeClient.CoreV1(), aliceProjectName); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if err := annotateNamespace(clusterAdminKubeClient.CoreV1(), requesterKey, "alice", aliceProjectName); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := annotateNamespace(clusterAdminKubeClient.CoreV1(), requesterKey, "bob", bobProjectName); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if err := waitForQuotaLabeling(clusterAdminQuotaClient, aliceProjectName); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// bobProjectName doesn't match the annotation selector's value and should never pick
+			// up the CRQ.
+			if err := waitForQuotaLabeling(clusterAdminQuotaClient, bobProjectName); err == nil {
+				t.Fatalf("expected namespace annotated %s=bob to remain unselected", requesterKey)
+			}
+
+			configmap := &corev1.ConfigMap{}
+			configmap.GenerateName = "test"
+			if _, err := clusterAdminKubeClient.CoreV1().ConfigMaps(aliceProjectName).Create(context.Background(), configmap, metav1.CreateOptions{}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := waitForForbidden(func() error {
+				_, err := clusterAdminKubeClient.CoreV1().ConfigMaps(aliceProjectName).Create(context.Background(), configmap, metav1.CreateOptions{})
+				return err
+			}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// Re-annotating to the matching value should dynamically bring the namespace into the
+			// selector's scope on the next accounting cycle.
+			if err := annotateNamespace(clusterAdminKubeClient.CoreV1(), requesterKey, "alice", bobProjectName); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := waitForQuotaLabeling(clusterAdminQuotaClient, bobProjectName); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+
+		g.It("should select namespaces by the intersection of a label selector and an annotation selector [apigroup:quota.openshift.io]", func() {
+			t := g.GinkgoT(1)
+
+			clusterAdminKubeClient := oc.AdminKubeClient()
+			clusterAdminQuotaClient := oc.AdminQuotaClient()
+
+			labelSelectorKey := "tier-" + oc.Namespace()
+			requesterKey := "openshift.io/requester-" + oc.Namespace()
+
+			cq := &quotav1.ClusterResourceQuota{
+				ObjectMeta: metav1.ObjectMeta{Name: "intersect-" + oc.Namespace()},
+				Spec: quotav1.ClusterResourceQuotaSpec{
+					Selector: quotav1.ClusterResourceQuotaSelector{
+						LabelSelector:      &metav1.LabelSelector{MatchLabels: map[string]string{labelSelectorKey: "bar"}},
+						AnnotationSelector: map[string]string{requesterKey: "alice"},
+					},
+					Quota: corev1.ResourceQuotaSpec{
+						Hard: corev1.ResourceList{
+							corev1.ResourceConfigMaps: resource.MustParse(fmt.Sprintf("%d", 1+len(mandatoryConfigMapNames))),
+						},
+					},
+				},
+			}
+			if _, err := clusterAdminQuotaClient.QuotaV1().ClusterResourceQuotas().Create(context.Background(), cq, metav1.CreateOptions{}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			oc.AddResourceToDelete(quotav1.GroupVersion.WithResource("clusterresourcequotas"), cq)
+
+			bothProjectName := oc.SetupProject()
+			labelOnlyProjectName := oc.SetupProject()
+
+			if err := waitForMandatoryConfigMaps(clusterAdminKubeClient.CoreV1(), bothProjectName); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// bothProjectName satisfies both halves of the selector and should be picked up.
+			if err := labelNamespace(clusterAdminKubeClient.CoreV1(), labelSelectorKey, bothProjectName); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := annotateNamespace(clusterAdminKubeClient.CoreV1(), requesterKey, "alice", bothProjectName); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// labelOnlyProjectName only satisfies the label half and must remain unselected.
+			if err := labelNamespace(clusterAdminKubeClient.CoreV1(), labelSelectorKey, labelOnlyProjectName); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if err := waitForQuotaLabeling(clusterAdminQuotaClient, bothProjectName); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := waitForQuotaLabeling(clusterAdminQuotaClient, labelOnlyProjectName); err == nil {
+				t.Fatalf("expected namespace matching only the label selector to remain unselected")
+			}
+
+			configmap := &corev1.ConfigMap{}
+			configmap.GenerateName = "test"
+			if _, err := clusterAdminKubeClient.CoreV1().ConfigMaps(bothProjectName).Create(context.Background(), configmap, metav1.CreateOptions{}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := waitForForbidden(func() error {
+				_, err := clusterAdminKubeClient.CoreV1().ConfigMaps(bothProjectName).Create(context.Background(), configmap, metav1.CreateOptions{})
+				return err
+			}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// labelOnlyProjectName is never selected, so it is free of the quota entirely.
+			if _, err := clusterAdminKubeClient.CoreV1().ConfigMaps(labelOnlyProjectName).Create(context.Background(), configmap, metav1.CreateOptions{}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	})
+})
+
+// annotateNamespace sets (or, when value is empty, removes) an annotation on an existing namespace.
+func annotateNamespace(clusterAdminKubeClient corev1client.NamespacesGetter, annotationKey, value, namespaceName string) error {
+	ns, err := clusterAdminKubeClient.Namespaces().Get(context.Background(), namespaceName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if ns.Annotations == nil {
+		ns.Annotations = map[string]string{}
+	}
+	if value == "" {
+		delete(ns.Annotations, annotationKey)
+	} else {
+		ns.Annotations[annotationKey] = value
+	}
+	if _, err := clusterAdminKubeClient.Namespaces().Update(context.Background(), ns, metav1.UpdateOptions{}); err != nil {
+		return err
+	}
+	return nil
+}